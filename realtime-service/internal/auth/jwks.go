@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JWKSCache fetches and caches the RSA public keys published at a JWKS
+// URL, refreshing once the TTL has passed (or sooner, when a token names
+// a kid the cache doesn't hold) so verifying a token doesn't cost a
+// network round trip on every upgrade.
+type JWKSCache struct {
+	URL string
+	TTL time.Duration
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+// NewJWKSCache returns a cache for the keys published at url with a
+// 10-minute TTL.
+func NewJWKSCache(url string) *JWKSCache {
+	return &JWKSCache{URL: url, TTL: 10 * time.Minute}
+}
+
+type jwksDocument struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// Key returns the RSA public key for kid, refreshing the cache first if
+// it's stale or doesn't have kid yet (covers key rotation mid-TTL).
+func (c *JWKSCache) Key(kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.keys[kid]; ok && time.Since(c.fetched) < c.TTL {
+		return key, nil
+	}
+
+	if err := c.refreshLocked(); err != nil {
+		if key, ok := c.keys[kid]; ok {
+			return key, nil // serve stale rather than fail every request on a JWKS blip
+		}
+		return nil, err
+	}
+
+	key, ok := c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("auth: no JWKS key for kid %q", kid)
+	}
+	return key, nil
+}
+
+func (c *JWKSCache) refreshLocked() error {
+	resp, err := http.Get(c.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var doc jwksDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		pub, err := rsaPublicKey(k.N, k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	c.keys = keys
+	c.fetched = time.Now()
+	return nil
+}
+
+func rsaPublicKey(nEnc, eEnc string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(nEnc)
+	if err != nil {
+		return nil, err
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(eEnc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowedOriginsExactHost(t *testing.T) {
+	a := AllowedOrigins{"app.example.com"}
+
+	if !a.Allows("https://app.example.com") {
+		t.Error("Allows(https://app.example.com) = false, want true")
+	}
+	if a.Allows("https://evil.com") {
+		t.Error("Allows(https://evil.com) = true, want false")
+	}
+}
+
+func TestAllowedOriginsWildcardSubdomain(t *testing.T) {
+	a := AllowedOrigins{"*.example.com"}
+
+	if !a.Allows("https://foo.example.com") {
+		t.Error("Allows(https://foo.example.com) = false, want true")
+	}
+	if !a.Allows("http://bar.example.com:8080") {
+		t.Error("Allows(http://bar.example.com:8080) = false, want true")
+	}
+	if a.Allows("https://example.com") {
+		t.Error("Allows(https://example.com) = true, want false; wildcard must not match the bare domain")
+	}
+	if a.Allows("https://notexample.com") {
+		t.Error("Allows(https://notexample.com) = true, want false")
+	}
+}
+
+func TestAllowedOriginsEmptyListPermitsNothing(t *testing.T) {
+	var a AllowedOrigins
+
+	if a.Allows("https://app.example.com") {
+		t.Error("empty AllowedOrigins permitted an origin, want none allowed")
+	}
+}
+
+func TestAllowedOriginsRejectsEmptyOrigin(t *testing.T) {
+	a := AllowedOrigins{"app.example.com"}
+
+	if a.Allows("") {
+		t.Error("Allows(\"\") = true, want false")
+	}
+}
+
+func TestAllowedOriginsCheckOriginReadsHeader(t *testing.T) {
+	a := AllowedOrigins{"app.example.com"}
+	r := httptest.NewRequest(http.MethodGet, "/session", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+
+	if !a.CheckOrigin(r) {
+		t.Error("CheckOrigin = false, want true")
+	}
+}
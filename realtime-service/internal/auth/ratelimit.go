@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter enforces the two upgrade-time limits a session must pass
+// before the handshake completes: a cap on concurrently open sessions
+// per authenticated subject, and a token-bucket cap on new upgrade
+// attempts per client IP (checked before the token is even parsed, so it
+// also blunts unauthenticated connection floods).
+type RateLimiter struct {
+	MaxSessionsPerUser int
+	MaxUpgradesPerMin  int
+
+	mu       sync.Mutex
+	sessions map[string]int
+	buckets  map[string]*tokenBucket
+}
+
+// NewRateLimiter returns a limiter enforcing the given per-user and
+// per-IP limits.
+func NewRateLimiter(maxSessionsPerUser, maxUpgradesPerMin int) *RateLimiter {
+	return &RateLimiter{
+		MaxSessionsPerUser: maxSessionsPerUser,
+		MaxUpgradesPerMin:  maxUpgradesPerMin,
+		sessions:           make(map[string]int),
+		buckets:            make(map[string]*tokenBucket),
+	}
+}
+
+// AllowUpgrade reports whether r's client IP still has upgrade attempts
+// left in the current window.
+func (l *RateLimiter) AllowUpgrade(r *http.Request) bool {
+	ip := clientIP(r)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[ip]
+	if !ok {
+		b = newTokenBucket(l.MaxUpgradesPerMin, time.Minute)
+		l.buckets[ip] = b
+	}
+	return b.take()
+}
+
+// AcquireSession reserves one of sub's session slots, returning false if
+// they're already at MaxSessionsPerUser. Callers must pair a true result
+// with a later ReleaseSession.
+func (l *RateLimiter) AcquireSession(sub string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.sessions[sub] >= l.MaxSessionsPerUser {
+		return false
+	}
+	l.sessions[sub]++
+	return true
+}
+
+// ReleaseSession frees one of sub's session slots.
+func (l *RateLimiter) ReleaseSession(sub string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.sessions[sub] == 0 {
+		return
+	}
+	l.sessions[sub]--
+	if l.sessions[sub] == 0 {
+		delete(l.sessions, sub)
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// tokenBucket is a fixed-window counter: it holds up to capacity tokens
+// and refills to capacity at the start of every window.
+type tokenBucket struct {
+	capacity int
+	window   time.Duration
+
+	tokens     int
+	windowEnds time.Time
+}
+
+func newTokenBucket(capacity int, window time.Duration) *tokenBucket {
+	return &tokenBucket{
+		capacity:   capacity,
+		window:     window,
+		tokens:     capacity,
+		windowEnds: time.Now().Add(window),
+	}
+}
+
+func (b *tokenBucket) take() bool {
+	now := time.Now()
+	if now.After(b.windowEnds) {
+		b.tokens = b.capacity
+		b.windowEnds = now.Add(b.window)
+	}
+	if b.tokens <= 0 {
+		return false
+	}
+	b.tokens--
+	return true
+}
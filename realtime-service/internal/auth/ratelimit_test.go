@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimiterAcquireSessionEnforcesLimit(t *testing.T) {
+	l := NewRateLimiter(2, 0)
+
+	if !l.AcquireSession("alice") {
+		t.Fatal("first AcquireSession failed, want true")
+	}
+	if !l.AcquireSession("alice") {
+		t.Fatal("second AcquireSession failed, want true")
+	}
+	if l.AcquireSession("alice") {
+		t.Fatal("third AcquireSession succeeded, want false (over MaxSessionsPerUser)")
+	}
+
+	l.ReleaseSession("alice")
+	if !l.AcquireSession("alice") {
+		t.Fatal("AcquireSession after ReleaseSession failed, want true")
+	}
+}
+
+func TestRateLimiterAcquireSessionPerSubject(t *testing.T) {
+	l := NewRateLimiter(1, 0)
+
+	if !l.AcquireSession("alice") {
+		t.Fatal("AcquireSession(alice) failed, want true")
+	}
+	if !l.AcquireSession("bob") {
+		t.Fatal("AcquireSession(bob) failed, want true, limits are per-subject")
+	}
+}
+
+func TestRateLimiterReleaseSessionBelowZeroIsNoop(t *testing.T) {
+	l := NewRateLimiter(1, 0)
+	l.ReleaseSession("alice") // never acquired; must not panic or go negative
+
+	if !l.AcquireSession("alice") {
+		t.Fatal("AcquireSession after spurious ReleaseSession failed, want true")
+	}
+}
+
+func TestRateLimiterAllowUpgradeEnforcesLimit(t *testing.T) {
+	l := NewRateLimiter(0, 2)
+	r := httptest.NewRequest(http.MethodGet, "/session", nil)
+	r.RemoteAddr = "203.0.113.1:54321"
+
+	if !l.AllowUpgrade(r) {
+		t.Fatal("first AllowUpgrade failed, want true")
+	}
+	if !l.AllowUpgrade(r) {
+		t.Fatal("second AllowUpgrade failed, want true")
+	}
+	if l.AllowUpgrade(r) {
+		t.Fatal("third AllowUpgrade succeeded, want false (over MaxUpgradesPerMin)")
+	}
+}
+
+func TestRateLimiterAllowUpgradePerIP(t *testing.T) {
+	l := NewRateLimiter(0, 1)
+
+	r1 := httptest.NewRequest(http.MethodGet, "/session", nil)
+	r1.RemoteAddr = "203.0.113.1:1"
+	r2 := httptest.NewRequest(http.MethodGet, "/session", nil)
+	r2.RemoteAddr = "203.0.113.2:1"
+
+	if !l.AllowUpgrade(r1) {
+		t.Fatal("AllowUpgrade(ip1) failed, want true")
+	}
+	if !l.AllowUpgrade(r2) {
+		t.Fatal("AllowUpgrade(ip2) failed, want true, limits are per-IP")
+	}
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tokenProtocolPrefix marks the Sec-WebSocket-Protocol entry that
+// carries a session token, for clients that can't set a custom
+// Authorization header on the upgrade request (most browser WebSocket
+// APIs). "." and "-" are valid HTTP token characters, so a JWT can be
+// used as the subprotocol value directly.
+const tokenProtocolPrefix = "bearer."
+
+// Claims is the subset of the session JWT's claims the handler needs;
+// Subject is used as the rate limiter's per-user key.
+type Claims struct {
+	jwt.RegisteredClaims
+}
+
+// VerifyToken parses and verifies tokenString's signature against keys,
+// rejecting anything not signed with RS256.
+func VerifyToken(tokenString string, keys *JWKSCache) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != "RS256" {
+			return nil, fmt.Errorf("auth: unexpected signing method %q", t.Method.Alg())
+		}
+		kid, _ := t.Header["kid"].(string)
+		return keys.Key(kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// TokenFromRequest extracts the session token from r: a ?token= query
+// parameter, or the Sec-WebSocket-Protocol subprotocol trick.
+func TokenFromRequest(r *http.Request) string {
+	if t := r.URL.Query().Get("token"); t != "" {
+		return t
+	}
+
+	for _, p := range websocketProtocols(r) {
+		if rest, ok := strings.CutPrefix(p, tokenProtocolPrefix); ok {
+			return rest
+		}
+	}
+	return ""
+}
+
+// websocketProtocols splits the (possibly multi-valued,
+// comma-separated) Sec-WebSocket-Protocol header into its offered
+// protocol names.
+func websocketProtocols(r *http.Request) []string {
+	var out []string
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, p := range strings.Split(header, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
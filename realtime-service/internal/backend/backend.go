@@ -0,0 +1,72 @@
+// Package backend defines the adapter boundary between the session
+// handler and whatever actually produces a lesson turn: EchoBackend for
+// tests and local development, InworldBackend for the real runtime.
+package backend
+
+import (
+	"context"
+
+	"github.com/inworld-ai/runtime-language-learning-app/realtime-service/internal/protocol"
+)
+
+// Config carries the per-session setup a backend needs to start,
+// sourced from the client's "config" ClientEvent.
+type Config struct {
+	Language string
+	Level    string
+}
+
+// Backend starts new backend sessions. Which implementation runs is
+// chosen at process startup via the SESSION_BACKEND environment
+// variable ("echo" or "inworld").
+type Backend interface {
+	Start(ctx context.Context, cfg Config) (Session, error)
+}
+
+// Session is one running backend conversation. The handler pumps
+// browser input into SendAudio/SendText and drains browser-bound output
+// from Events; Close ends the backend side when the browser
+// disconnects.
+type Session interface {
+	SendAudio(pcm []byte) error
+	SendText(text string) error
+	Events() <-chan protocol.ServerEvent
+	Close() error
+}
+
+// enqueue delivers ev onto ch under the handler's backpressure policy:
+// audio events drop the oldest buffered audio frame to make room when
+// the channel is full (freshness over completeness, since a lagging
+// listener doesn't want a backlog of stale audio), while every other
+// event type blocks so control/text events are never silently lost.
+// An audio event that finds no audio frame buffered blocks as well.
+func enqueue(ch chan protocol.ServerEvent, ev protocol.ServerEvent) {
+	if ev.Type != "agent_audio" {
+		ch <- ev
+		return
+	}
+
+	select {
+	case ch <- ev:
+		return
+	default:
+	}
+
+	// Full: take everything buffered, drop the oldest audio frame among
+	// it, and put the rest back in order ahead of ev.
+	var kept []protocol.ServerEvent
+	for dropped, more := false, true; more; {
+		select {
+		case old := <-ch:
+			if dropped || old.Type != "agent_audio" {
+				kept = append(kept, old)
+			}
+			dropped = dropped || old.Type == "agent_audio"
+		default:
+			more = false
+		}
+	}
+	for _, old := range append(kept, ev) {
+		ch <- old
+	}
+}
@@ -0,0 +1,49 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/inworld-ai/runtime-language-learning-app/realtime-service/internal/protocol"
+)
+
+// EchoBackend preserves the handler's original placeholder behavior: it
+// plays back whatever the client sent as the agent's turn, narrating the
+// thinking/speaking states a real backend would go through. It needs no
+// external runtime, so it's what tests and local development default to.
+type EchoBackend struct{}
+
+// eventBuffer is how many ServerEvents Start buffers before SendAudio's
+// drop-oldest policy kicks in.
+const eventBuffer = 16
+
+type echoSession struct {
+	events chan protocol.ServerEvent
+}
+
+// Start returns a session immediately; EchoBackend has no external
+// dependency to fail on.
+func (EchoBackend) Start(_ context.Context, _ Config) (Session, error) {
+	return &echoSession{events: make(chan protocol.ServerEvent, eventBuffer)}, nil
+}
+
+func (s *echoSession) SendAudio(pcm []byte) error {
+	enqueue(s.events, protocol.ServerEvent{Type: "agent_audio", Audio: pcm})
+	return nil
+}
+
+func (s *echoSession) SendText(text string) error {
+	enqueue(s.events, protocol.ServerEvent{Type: "state", State: protocol.StateThinking})
+	enqueue(s.events, protocol.ServerEvent{Type: "state", State: protocol.StateSpeaking})
+	enqueue(s.events, protocol.ServerEvent{Type: "agent_text", Text: text})
+	enqueue(s.events, protocol.ServerEvent{Type: "state", State: protocol.StateIdle})
+	return nil
+}
+
+func (s *echoSession) Events() <-chan protocol.ServerEvent {
+	return s.events
+}
+
+func (s *echoSession) Close() error {
+	close(s.events)
+	return nil
+}
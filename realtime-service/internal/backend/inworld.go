@@ -0,0 +1,109 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+
+	"github.com/inworld-ai/runtime-language-learning-app/realtime-service/internal/protocol"
+)
+
+// InworldBackend opens an outbound WebSocket to a running Inworld
+// runtime and proxies audio/text between it and the browser session.
+// The runtime speaks the same JSON envelope shape as
+// protocol.ServerEvent for agent output, so this adapter doesn't need a
+// second parser — only a second connection.
+type InworldBackend struct {
+	// URL is the Inworld runtime's WebSocket endpoint, e.g.
+	// "wss://runtime.inworld.internal/v1/stream". Falls back to the
+	// INWORLD_RUNTIME_URL environment variable when empty.
+	URL string
+}
+
+type inworldSession struct {
+	conn   net.Conn
+	events chan protocol.ServerEvent
+	cancel context.CancelFunc
+}
+
+// Start dials the runtime and begins pumping its events into the
+// returned Session. The dial itself is not retried; callers that want
+// resilience should wrap Start with their own backoff.
+func (b InworldBackend) Start(ctx context.Context, _ Config) (Session, error) {
+	url := b.URL
+	if url == "" {
+		url = os.Getenv("INWORLD_RUNTIME_URL")
+	}
+	if url == "" {
+		return nil, fmt.Errorf("backend: INWORLD_RUNTIME_URL not set")
+	}
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	conn, _, _, err := ws.Dial(sessionCtx, url)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("backend: dial inworld runtime: %w", err)
+	}
+
+	s := &inworldSession{
+		conn:   conn,
+		events: make(chan protocol.ServerEvent, eventBuffer),
+		cancel: cancel,
+	}
+	go s.pump()
+	return s, nil
+}
+
+// pump reads events off the runtime connection until it closes or
+// errors, forwarding each to s.events under the same backpressure
+// policy EchoBackend uses. Binary frames carry raw agent audio and
+// become "agent_audio" events; text frames are JSON ServerEvents.
+func (s *inworldSession) pump() {
+	defer close(s.events)
+
+	for {
+		data, op, err := wsutil.ReadServerData(s.conn)
+		if err != nil {
+			return
+		}
+		if op == ws.OpBinary {
+			enqueue(s.events, protocol.ServerEvent{Type: "agent_audio", Audio: data})
+			continue
+		}
+		if op != ws.OpText {
+			continue
+		}
+
+		var ev protocol.ServerEvent
+		if err := json.Unmarshal(data, &ev); err != nil {
+			continue
+		}
+		enqueue(s.events, ev)
+	}
+}
+
+func (s *inworldSession) SendAudio(pcm []byte) error {
+	return wsutil.WriteClientMessage(s.conn, ws.OpBinary, pcm)
+}
+
+func (s *inworldSession) SendText(text string) error {
+	data, err := json.Marshal(protocol.ClientEvent{Type: "text", Text: text})
+	if err != nil {
+		return err
+	}
+	return wsutil.WriteClientMessage(s.conn, ws.OpText, data)
+}
+
+func (s *inworldSession) Events() <-chan protocol.ServerEvent {
+	return s.events
+}
+
+func (s *inworldSession) Close() error {
+	s.cancel()
+	return s.conn.Close()
+}
@@ -0,0 +1,28 @@
+// Package compress implements RFC 7692 permessage-deflate for the
+// realtime session WebSocket, trading CPU for bandwidth on the
+// repetitive JSON traffic (ASR partials, agent transcripts) a lesson
+// session generates.
+package compress
+
+import "compress/flate"
+
+// Config controls permessage-deflate behavior for one connection.
+// Threshold lets already-compressed payloads (opus audio chunks) skip
+// the deflate pass entirely, since compressing compressed data only
+// burns CPU for no size win.
+type Config struct {
+	Enabled   bool
+	Level     int // 1 (fastest) .. 9 (best), or flate.DefaultCompression
+	Threshold int // frames of at most this many bytes are sent uncompressed
+}
+
+// DefaultConfig is tuned for the JSON-heavy, low-latency traffic this
+// handler carries: a mid compression level and a threshold that skips
+// the smallest control/state events.
+func DefaultConfig() Config {
+	return Config{
+		Enabled:   true,
+		Level:     flate.DefaultCompression,
+		Threshold: 256,
+	}
+}
@@ -0,0 +1,59 @@
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+)
+
+// tail is the 4-byte empty DEFLATE block RFC 7692 §7.2.1 requires every
+// sender to strip from its own output and every receiver to restore
+// before inflating; it's what lets permessage-deflate reuse a plain
+// DEFLATE stream per message instead of one continuous stream per
+// connection.
+var tail = []byte{0x00, 0x00, 0xff, 0xff}
+
+// Compress deflate-compresses data at the given level and strips the
+// RFC 7692 trailing empty block.
+//
+// This always compresses as an independent message (equivalent to
+// negotiating no_context_takeover on both sides): a fresh flate.Writer
+// per call, rather than a sliding window kept across a connection's
+// messages. That costs a little compression ratio on short messages in
+// exchange for a much simpler, state-free implementation — acceptable
+// here since most frames are either small JSON events or audio that
+// skips compression entirely via Config.Threshold.
+func Compress(data []byte, level int) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	out := buf.Bytes()
+	if bytes.HasSuffix(out, tail) {
+		out = out[:len(out)-len(tail)]
+	}
+	return out, nil
+}
+
+// Decompress reverses Compress by restoring the trailing empty block
+// before inflating.
+func Decompress(data []byte) ([]byte, error) {
+	r := flate.NewReader(io.MultiReader(bytes.NewReader(data), bytes.NewReader(tail)))
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// ShouldCompress reports whether a frame of the given size should be
+// deflated under cfg, skipping compression for frames at or below
+// Threshold.
+func (c Config) ShouldCompress(size int) bool {
+	return c.Enabled && size > c.Threshold
+}
@@ -0,0 +1,108 @@
+package compress
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// transcriptEvent mirrors the shape of the JSON a real lesson session
+// sends most often: ASR partials updating in place as the student
+// speaks, followed by a final transcript and the agent's reply.
+type transcriptEvent struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	State string `json:"state,omitempty"`
+}
+
+// syntheticTranscript builds events representative of a five-minute
+// lesson (one event every 300ms, mostly ASR partials, with a final and
+// an agent turn each every 6s, alternating 3s apart) — there's no
+// recorded session fixture in this repo, so this approximates the
+// traffic mix the benchmark cares about. ASR partials/finals are short,
+// but agent_text turns carry a full sentence or two of explanation —
+// those are what cross DefaultConfig's 256-byte Threshold and actually
+// exercise compression.
+func syntheticTranscript() [][]byte {
+	const events = 5 * 60 * 1000 / 300 // ~1000 events over 5 minutes
+	phrases := []string{
+		"I would like to order a coffee, please.",
+		"Where is the nearest train station?",
+		"Can you repeat that more slowly?",
+		"Thank you very much for your help.",
+	}
+	agentReplies := []string{
+		"Good question! To order a coffee politely, you can say 'I would like a coffee, please' or, more formally, 'Could I please have a coffee?' Both are common in everyday conversation. Notice how 'please' softens the request, which is something native speakers do almost automatically when talking to waitstaff.",
+		"The nearest train station is usually described with 'Where is the nearest train station?' or 'How do I get to the train station from here?' Either phrasing will be understood. You could also add 'on foot' or 'by bus' if you want directions tailored to how you're traveling.",
+		"Of course, I'll slow down. When you don't catch something, it's polite to say 'Could you repeat that more slowly, please?' or simply 'Sorry, could you say that again?' Both phrases are common classroom expressions you'll hear often, so it's worth practicing them until they feel natural.",
+		"You're very welcome! In English, 'Thank you very much for your help' is a warm way to express gratitude, and 'I really appreciate it' works nicely as a follow-up. In casual conversation, people often shorten this to just 'Thanks so much, I appreciate it.'",
+	}
+
+	out := make([][]byte, 0, events)
+	for i := 0; i < events; i++ {
+		phrase := phrases[i%len(phrases)]
+
+		var ev transcriptEvent
+		switch {
+		case i%20 == 19:
+			// An agent turn every 6s: a full explanatory reply, the
+			// kind of payload permessage-deflate is meant to shrink.
+			ev = transcriptEvent{Type: "agent_text", Text: agentReplies[i%len(agentReplies)]}
+		case i%10 == 9:
+			// The settled final transcript every 6s, 3s before each
+			// agent turn.
+			ev = transcriptEvent{Type: "asr_final", Text: phrase}
+		default:
+			// A partial grows a few characters at a time as the
+			// student speaks.
+			grown := len(phrase) * (1 + i%9) / 9
+			if grown == 0 {
+				grown = 1
+			}
+			ev = transcriptEvent{Type: "asr_partial", Text: phrase[:grown], State: "listening"}
+		}
+
+		data, err := json.Marshal(ev)
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, data)
+	}
+	return out
+}
+
+// BenchmarkBytesOnWire reports bytes transferred for the synthetic
+// transcript with and without permessage-deflate, via b.ReportMetric, so
+// `go test -bench BytesOnWire -benchtime=1x` prints the comparison
+// directly instead of needing a separate tool.
+func BenchmarkBytesOnWire(b *testing.B) {
+	events := syntheticTranscript()
+	cfg := DefaultConfig()
+
+	b.Run("uncompressed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var total int
+			for _, ev := range events {
+				total += len(ev)
+			}
+			b.ReportMetric(float64(total), "bytes/op")
+		}
+	})
+
+	b.Run("permessage-deflate", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var total int
+			for _, ev := range events {
+				if !cfg.ShouldCompress(len(ev)) {
+					total += len(ev)
+					continue
+				}
+				out, err := Compress(ev, cfg.Level)
+				if err != nil {
+					b.Fatal(err)
+				}
+				total += len(out)
+			}
+			b.ReportMetric(float64(total), "bytes/op")
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package compress
+
+import "github.com/gobwas/httphead"
+
+// ExtensionToken is the Sec-WebSocket-Extensions token for RFC 7692.
+const ExtensionToken = "permessage-deflate"
+
+// Params records what was negotiated for one connection, driven off the
+// client's offered parameters and reported back via the response
+// extension line.
+type Params struct {
+	// ClientMaxWindowBits is the client_max_window_bits value the peer
+	// offered, if any. We don't currently vary our window size off of
+	// it; it's recorded for visibility/future use.
+	ClientMaxWindowBits int
+}
+
+// Negotiate returns a ws.HTTPUpgrader.Negotiate callback that accepts
+// permessage-deflate when cfg.Enabled and the client offered it. Because
+// Compress/Decompress only ever handle one message's worth of DEFLATE
+// state at a time (see deflate.go), the response substitutes
+// ResponseParameters in place of whatever the client offered, asserting
+// both server_no_context_takeover and client_no_context_takeover
+// regardless of what was asked for — those are valid unilateral
+// restrictions under RFC 7692 §7.1.2.2, and are what keep this
+// implementation state-free. ws.HTTPUpgrader.Extension can only accept
+// or reject the client's own offer verbatim, so it can't make this
+// substitution; Negotiate is what lets the response diverge from it.
+func Negotiate(cfg Config, params *Params) func(httphead.Option) (httphead.Option, error) {
+	return func(opt httphead.Option) (httphead.Option, error) {
+		if !cfg.Enabled || string(opt.Name) != ExtensionToken {
+			return httphead.Option{}, nil
+		}
+
+		opt.Parameters.ForEach(func(key, value []byte) bool {
+			if string(key) == "client_max_window_bits" {
+				if n, ok := parseWindowBits(value); ok {
+					params.ClientMaxWindowBits = n
+				}
+			}
+			return true
+		})
+		return ResponseParameters(), nil
+	}
+}
+
+// ResponseParameters builds the permessage-deflate response parameters
+// to echo back in Sec-WebSocket-Extensions, asserting the
+// no-context-takeover restriction this implementation relies on.
+func ResponseParameters() httphead.Option {
+	return httphead.NewOption(ExtensionToken, map[string]string{
+		"server_no_context_takeover": "",
+		"client_no_context_takeover": "",
+	})
+}
+
+func parseWindowBits(value []byte) (int, bool) {
+	if len(value) == 0 {
+		return 0, false
+	}
+	n := 0
+	for _, b := range value {
+		if b < '0' || b > '9' {
+			return 0, false
+		}
+		n = n*10 + int(b-'0')
+	}
+	return n, true
+}
@@ -0,0 +1,46 @@
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"testing"
+)
+
+func TestCompressDecompressRoundTrip(t *testing.T) {
+	cases := [][]byte{
+		[]byte(""),
+		[]byte("a"),
+		[]byte(`{"type":"asr_partial","text":"Where is the","state":"listening"}`),
+		bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog "), 100),
+	}
+
+	for _, in := range cases {
+		out, err := Compress(in, flate.DefaultCompression)
+		if err != nil {
+			t.Fatalf("Compress(%q): %v", in, err)
+		}
+		got, err := Decompress(out)
+		if err != nil {
+			t.Fatalf("Decompress(Compress(%q)): %v", in, err)
+		}
+		if !bytes.Equal(got, in) {
+			t.Fatalf("round trip mismatch: got %q, want %q", got, in)
+		}
+	}
+}
+
+func TestShouldCompress(t *testing.T) {
+	cfg := Config{Enabled: true, Threshold: 256}
+
+	if cfg.ShouldCompress(256) {
+		t.Error("ShouldCompress(256) = true, want false (at threshold)")
+	}
+	if !cfg.ShouldCompress(257) {
+		t.Error("ShouldCompress(257) = false, want true (over threshold)")
+	}
+
+	cfg.Enabled = false
+	if cfg.ShouldCompress(1000) {
+		t.Error("ShouldCompress with Enabled=false = true, want false")
+	}
+}
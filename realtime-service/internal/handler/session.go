@@ -0,0 +1,333 @@
+// Package handler implements the /session HTTP handler as a thin bridge:
+// it upgrades the connection, negotiates the protocol and compression,
+// and pumps frames between the browser and a backend.Session. All of the
+// actual conversation logic lives in the backend.
+package handler
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/gobwas/httphead"
+	"github.com/gobwas/ws"
+
+	"github.com/inworld-ai/runtime-language-learning-app/realtime-service/internal/auth"
+	"github.com/inworld-ai/runtime-language-learning-app/realtime-service/internal/backend"
+	"github.com/inworld-ai/runtime-language-learning-app/realtime-service/internal/compress"
+	"github.com/inworld-ai/runtime-language-learning-app/realtime-service/internal/protocol"
+	"github.com/inworld-ai/runtime-language-learning-app/realtime-service/internal/session"
+)
+
+// connState is what the handler tracks per browser connection: the
+// turn-taking state machine, negotiated compression, the backend
+// session frames are bridged to (nil until the client's config frame
+// starts it), the authenticated subject whose rate-limit slot needs
+// releasing on cleanup, and the session config the client sent. Fields
+// are guarded by Handler.mu.
+type connState struct {
+	machine     *protocol.StateMachine
+	compression compress.Config
+	backend     backend.Session
+	ctx         context.Context
+	cancel      context.CancelFunc
+	subject     string
+	config      *protocol.SessionConfig
+}
+
+// Handler bridges browser WebSocket connections to a backend.Backend,
+// after the connection has cleared origin, auth, and rate-limit checks.
+// It owns the SessionHub that drives reads.
+type Handler struct {
+	Backend backend.Backend
+	Hub     *session.SessionHub
+
+	Origins auth.AllowedOrigins
+	Tokens  *auth.JWKSCache
+	Limiter *auth.RateLimiter
+
+	mu    sync.Mutex
+	conns map[net.Conn]*connState
+}
+
+// New creates a Handler backed by back. It creates and starts the
+// SessionHub; callers must call Close on shutdown. Origins, Tokens, and
+// Limiter are left unset (no WebSocket handshake will ever pass the
+// handshake checks) and must be assigned before Serving — there is no
+// same default as the old CheckOrigin-always-true handler.
+func New(back backend.Backend) (*Handler, error) {
+	h := &Handler{Backend: back, conns: make(map[net.Conn]*connState)}
+
+	hub, err := session.NewSessionHub(h.handleFrame)
+	if err != nil {
+		return nil, err
+	}
+	h.Hub = hub
+	return h, nil
+}
+
+// ServeHTTP checks origin, auth token, and rate limits before upgrading
+// the request to a WebSocket; only then does it negotiate the session
+// protocol version and permessage-deflate, and register the connection
+// with the hub. The backend session itself doesn't start until the
+// client's first config frame arrives — see startBackend — since that's
+// the only point a real backend.Config exists to start it with.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.Origins.CheckOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	if h.Limiter != nil && !h.Limiter.AllowUpgrade(r) {
+		http.Error(w, "too many upgrade attempts", http.StatusTooManyRequests)
+		return
+	}
+
+	token := auth.TokenFromRequest(r)
+	if token == "" {
+		http.Error(w, "missing session token", http.StatusUnauthorized)
+		return
+	}
+	claims, err := auth.VerifyToken(token, h.Tokens)
+	if err != nil {
+		http.Error(w, "invalid session token", http.StatusUnauthorized)
+		return
+	}
+	subject := claims.Subject
+
+	if h.Limiter != nil && !h.Limiter.AcquireSession(subject) {
+		http.Error(w, "too many concurrent sessions", http.StatusTooManyRequests)
+		return
+	}
+
+	var params compress.Params
+	upgrader := ws.HTTPUpgrader{
+		Protocol:  protocol.AcceptVersion,
+		Negotiate: compress.Negotiate(compress.DefaultConfig(), &params),
+	}
+
+	conn, _, hs, err := upgrader.Upgrade(r, w)
+	if err != nil {
+		log.Println("handler: upgrade failed:", err)
+		if h.Limiter != nil {
+			h.Limiter.ReleaseSession(subject)
+		}
+		return
+	}
+
+	if hs.Protocol != protocol.Version {
+		if err := protocol.RejectVersionMismatch(conn); err != nil {
+			log.Println("handler: RejectVersionMismatch failed:", err)
+		}
+		if h.Limiter != nil {
+			h.Limiter.ReleaseSession(subject)
+		}
+		return
+	}
+
+	cfg := compress.DefaultConfig()
+	cfg.Enabled = hasExtension(hs.Extensions, compress.ExtensionToken)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cs := &connState{
+		machine:     protocol.NewStateMachine(),
+		compression: cfg,
+		ctx:         ctx,
+		cancel:      cancel,
+		subject:     subject,
+	}
+
+	h.mu.Lock()
+	h.conns[conn] = cs
+	h.mu.Unlock()
+
+	if err := h.Hub.Register(conn); err != nil {
+		log.Println("handler: register failed:", err)
+		h.cleanup(conn)
+	}
+}
+
+// pumpEvents drains cs.backend's Events channel to conn until the
+// backend closes it or a write fails.
+func (h *Handler) pumpEvents(conn net.Conn, cs *connState) {
+	for ev := range cs.backend.Events() {
+		if ev.Type == "state" {
+			// Best-effort bookkeeping only: a backend-driven transition
+			// that doesn't match our local machine is logged, not fatal,
+			// since the backend is the source of truth for its own turn.
+			if err := cs.machine.Transition(ev.State); err != nil {
+				log.Println("handler:", err)
+			}
+		}
+
+		if ev.Type == "agent_audio" {
+			err := h.Hub.Write(conn, func(c net.Conn) error {
+				return protocol.WriteAudio(c, ev.Audio, compress.Config{})
+			})
+			if err != nil {
+				h.cleanup(conn)
+				return
+			}
+			continue
+		}
+
+		err := h.Hub.Write(conn, func(c net.Conn) error {
+			return protocol.WriteEventCompressed(c, ev, cs.compression)
+		})
+		if err != nil {
+			h.cleanup(conn)
+			return
+		}
+	}
+}
+
+// handleFrame is the SessionHub MessageHandler: it decodes one client
+// event and forwards it to the connection's backend session.
+func (h *Handler) handleFrame(conn net.Conn) error {
+	ev, err := protocol.ReadEventNotifyPong(conn, func() { h.Hub.Touch(conn) })
+	if err != nil {
+		h.cleanup(conn)
+		return err
+	}
+
+	h.mu.Lock()
+	cs := h.conns[conn]
+	h.mu.Unlock()
+	if cs == nil {
+		return nil
+	}
+
+	switch ev.Type {
+	case "config":
+		// Session setup, not a conversational turn: start the backend
+		// now that there's a real config to start it with, and leave
+		// the turn-taking state machine alone.
+		return h.startBackend(conn, cs, ev.Config)
+	case "audio_chunk":
+		bsess, ok := h.backendSession(cs)
+		if !ok {
+			log.Println("handler: audio_chunk before config, dropping")
+			return nil
+		}
+		h.advance(conn, cs, protocol.StateListening)
+		return bsess.SendAudio(ev.Audio)
+	case "interrupt":
+		h.advance(conn, cs, protocol.StateIdle)
+		return nil
+	default:
+		bsess, ok := h.backendSession(cs)
+		if !ok {
+			log.Println("handler: text before config, dropping")
+			return nil
+		}
+		h.advance(conn, cs, protocol.StateListening)
+		return bsess.SendText(ev.Text)
+	}
+}
+
+// startBackend starts cs's backend session using the client-supplied
+// config and kicks off the goroutine that pumps its output to conn. It
+// is idempotent: a duplicate config frame is ignored rather than
+// starting a second backend session for the same connection.
+func (h *Handler) startBackend(conn net.Conn, cs *connState, sc *protocol.SessionConfig) error {
+	h.mu.Lock()
+	if cs.backend != nil {
+		h.mu.Unlock()
+		return nil
+	}
+	cs.config = sc
+	h.mu.Unlock()
+
+	cfg := backend.Config{}
+	if sc != nil {
+		cfg.Language = sc.Language
+		cfg.Level = sc.Level
+	}
+
+	bsess, err := h.Backend.Start(cs.ctx, cfg)
+	if err != nil {
+		log.Println("handler: backend start failed:", err)
+		return err
+	}
+
+	h.mu.Lock()
+	cs.backend = bsess
+	h.mu.Unlock()
+
+	go h.pumpEvents(conn, cs)
+	return nil
+}
+
+// backendSession returns cs's backend session and whether the client has
+// sent its config frame yet — audio/text frames that arrive first have
+// nothing to forward to.
+func (h *Handler) backendSession(cs *connState) (backend.Session, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return cs.backend, cs.backend != nil
+}
+
+// advance transitions cs's state machine to next and emits the
+// corresponding "state" event, ignoring a transition that's already
+// where it needs to be.
+func (h *Handler) advance(conn net.Conn, cs *connState, next protocol.State) {
+	if cs.machine.Current() == next {
+		return
+	}
+	if err := cs.machine.Transition(next); err != nil {
+		log.Println("handler:", err)
+		return
+	}
+	err := h.Hub.Write(conn, func(c net.Conn) error {
+		return protocol.WriteEventCompressed(c, protocol.ServerEvent{Type: "state", State: next}, cs.compression)
+	})
+	if err != nil {
+		h.cleanup(conn)
+	}
+}
+
+// cleanup unregisters conn, closes its backend session (if the client
+// ever sent a config frame to start one), and forgets its state. Safe to
+// call more than once.
+func (h *Handler) cleanup(conn net.Conn) {
+	h.Hub.Unregister(conn)
+	conn.Close()
+
+	h.mu.Lock()
+	cs, ok := h.conns[conn]
+	delete(h.conns, conn)
+	var bsess backend.Session
+	if ok {
+		bsess = cs.backend
+	}
+	h.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	cs.cancel()
+	if bsess != nil {
+		bsess.Close()
+	}
+	if h.Limiter != nil {
+		h.Limiter.ReleaseSession(cs.subject)
+	}
+}
+
+// Close stops the hub, which sends every live connection a close frame
+// before releasing the poller.
+func (h *Handler) Close() error {
+	return h.Hub.Close()
+}
+
+func hasExtension(accepted []httphead.Option, token string) bool {
+	for _, opt := range accepted {
+		if string(opt.Name) == token {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+// Package protocol defines the inworld.session.v1 WebSocket sub-protocol:
+// the JSON envelopes exchanged between the browser and the session
+// handler, and the lesson turn-taking state machine layered on top of
+// them.
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+
+	"github.com/inworld-ai/runtime-language-learning-app/realtime-service/internal/compress"
+)
+
+// Version is negotiated via the Sec-WebSocket-Protocol header. Clients
+// that don't offer it are rejected before the state machine ever runs.
+const Version = "inworld.session.v1"
+
+// ClientEvent is sent by the browser. Type selects which of the optional
+// fields is populated; Audio and Text carry the payload for binary and
+// text frames respectively, Config carries session setup sent once at
+// the start of a session.
+type ClientEvent struct {
+	Type string `json:"type"` // "audio_chunk" | "text" | "interrupt" | "config"
+
+	Audio  []byte         `json:"-"`
+	Text   string         `json:"text,omitempty"`
+	Config *SessionConfig `json:"config,omitempty"`
+}
+
+// SessionConfig carries client-provided session setup, sent once as the
+// payload of a "config" ClientEvent.
+type SessionConfig struct {
+	Language string `json:"language,omitempty"`
+	Level    string `json:"level,omitempty"`
+}
+
+// ServerEvent is sent by the session handler. Type selects which of the
+// optional fields is populated.
+type ServerEvent struct {
+	Type string `json:"type"` // "asr_partial" | "asr_final" | "agent_text" | "agent_audio" | "state" | "error"
+
+	Text  string `json:"text,omitempty"`
+	Audio []byte `json:"-"` // raw payload for "agent_audio"; sent as a binary frame, never JSON
+	State State  `json:"state,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// errUnsupportedFrame is returned by ReadEvent when the frame's WebSocket
+// opcode is neither text nor binary.
+var errUnsupportedFrame = errors.New("protocol: unsupported frame opcode")
+
+// ReadEvent reads one WebSocket message from conn and decodes it as a
+// ClientEvent. Binary frames (raw PCM/opus audio) become an
+// "audio_chunk" event with Audio set; text frames are JSON-decoded
+// directly. Ping/close control frames are handled transparently.
+func ReadEvent(conn net.Conn) (ClientEvent, error) {
+	return ReadEventNotifyPong(conn, nil)
+}
+
+// ReadEventNotifyPong behaves like ReadEvent, additionally invoking
+// onPong whenever a pong control frame arrives, so a caller doing
+// ping/pong keepalive (session.SessionHub.Touch) can reset its read
+// deadline on liveness, not just on application data.
+func ReadEventNotifyPong(conn net.Conn, onPong func()) (ClientEvent, error) {
+	ctrl := wsutil.ControlFrameHandler(conn, ws.StateServerSide)
+	rd := wsutil.Reader{
+		Source: conn,
+		State:  ws.StateServerSide,
+	}
+	rd.OnIntermediate = func(h ws.Header, r io.Reader) error {
+		if h.OpCode == ws.OpPong && onPong != nil {
+			onPong()
+		}
+		return ctrl(h, r)
+	}
+
+	var hdr ws.Header
+	var err error
+	for {
+		hdr, err = rd.NextFrame()
+		if err != nil {
+			return ClientEvent{}, err
+		}
+		if !hdr.OpCode.IsControl() {
+			break
+		}
+		if err := rd.OnIntermediate(hdr, &rd); err != nil {
+			return ClientEvent{}, err
+		}
+	}
+
+	data, err := io.ReadAll(&rd)
+	if err != nil {
+		return ClientEvent{}, err
+	}
+
+	if hdr.Rsv1() {
+		data, err = compress.Decompress(data)
+		if err != nil {
+			return ClientEvent{}, err
+		}
+	}
+
+	switch hdr.OpCode {
+	case ws.OpBinary:
+		return ClientEvent{Type: "audio_chunk", Audio: data}, nil
+	case ws.OpText:
+		var ev ClientEvent
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return ClientEvent{}, err
+		}
+		return ev, nil
+	default:
+		return ClientEvent{}, errUnsupportedFrame
+	}
+}
+
+// WriteEvent JSON-encodes ev and writes it to conn as a single
+// uncompressed text frame. Callers that negotiated permessage-deflate
+// should use WriteEventCompressed instead.
+func WriteEvent(conn net.Conn, ev ServerEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return wsutil.WriteServerMessage(conn, ws.OpText, data)
+}
+
+// WriteEventCompressed behaves like WriteEvent, but deflates the JSON
+// payload per cfg (RFC 7692 permessage-deflate) when it's larger than
+// cfg.Threshold, setting RSV1 so the client knows to inflate it.
+func WriteEventCompressed(conn net.Conn, ev ServerEvent, cfg compress.Config) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return writeFrame(conn, ws.OpText, data, cfg)
+}
+
+// WriteAudio writes a raw audio payload to conn as a single binary
+// frame, bypassing JSON entirely so agent TTS output isn't
+// base64-inflated. Audio is typically opus-encoded already, so callers
+// generally pass a zero-value compress.Config (Enabled: false) rather
+// than spend CPU deflating incompressible bytes.
+func WriteAudio(conn net.Conn, payload []byte, cfg compress.Config) error {
+	return writeFrame(conn, ws.OpBinary, payload, cfg)
+}
+
+// writeFrame optionally deflates payload per cfg and writes a single
+// frame of the given opcode with RSV1 set if compression was applied.
+func writeFrame(conn net.Conn, op ws.OpCode, payload []byte, cfg compress.Config) error {
+	compressed := cfg.ShouldCompress(len(payload))
+	if compressed {
+		out, err := compress.Compress(payload, cfg.Level)
+		if err != nil {
+			return err
+		}
+		payload = out
+	}
+
+	header := ws.Header{
+		Fin:    true,
+		Rsv:    ws.Rsv(compressed, false, false),
+		OpCode: op,
+		Length: int64(len(payload)),
+	}
+	if err := ws.WriteHeader(conn, header); err != nil {
+		return err
+	}
+	_, err := conn.Write(payload)
+	return err
+}
@@ -0,0 +1,23 @@
+package protocol
+
+import (
+	"net"
+
+	"github.com/gobwas/ws"
+)
+
+// AcceptVersion is an ws.HTTPUpgrader.Protocol predicate that accepts
+// Version and rejects every other subprotocol the client offers.
+func AcceptVersion(p string) bool {
+	return p == Version
+}
+
+// RejectVersionMismatch closes conn with WebSocket close code 1002
+// (protocol error), the required response when a client connects
+// without negotiating Version.
+func RejectVersionMismatch(conn net.Conn) error {
+	defer conn.Close()
+	return ws.WriteFrame(conn, ws.NewCloseFrame(ws.NewCloseFrameBody(
+		ws.StatusProtocolError, "missing or unsupported "+Version+" subprotocol",
+	)))
+}
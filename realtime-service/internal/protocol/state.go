@@ -0,0 +1,63 @@
+package protocol
+
+import (
+	"fmt"
+	"sync"
+)
+
+// State is a lesson turn-taking state. The handler emits a "state"
+// ServerEvent on every transition so the frontend can render the right
+// UI (e.g. a mic indicator while Listening, a typing indicator while
+// Thinking).
+type State string
+
+const (
+	StateIdle      State = "idle"
+	StateListening State = "listening"
+	StateThinking  State = "thinking"
+	StateSpeaking  State = "speaking"
+)
+
+// transitions enumerates the only state changes the handler may make.
+// Anything not listed here is a bug in the caller, not a recoverable
+// runtime condition.
+var transitions = map[State]map[State]bool{
+	StateIdle:      {StateListening: true},
+	StateListening: {StateThinking: true, StateIdle: true},
+	StateThinking:  {StateSpeaking: true, StateIdle: true},
+	StateSpeaking:  {StateIdle: true},
+}
+
+// StateMachine tracks the current turn-taking state for one session and
+// rejects transitions that don't match the idle → listening → thinking →
+// speaking → idle cycle. A connection's handler reads and transitions it
+// from more than one goroutine over the connection's life (the frame
+// reader and the event pump), so access is guarded by mu.
+type StateMachine struct {
+	mu      sync.Mutex
+	current State
+}
+
+// NewStateMachine returns a StateMachine starting in StateIdle.
+func NewStateMachine() *StateMachine {
+	return &StateMachine{current: StateIdle}
+}
+
+// Current returns the state the machine is currently in.
+func (m *StateMachine) Current() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current
+}
+
+// Transition moves the machine to next, returning an error if that
+// transition isn't valid from the current state.
+func (m *StateMachine) Transition(next State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !transitions[m.current][next] {
+		return fmt.Errorf("protocol: invalid transition %s -> %s", m.current, next)
+	}
+	m.current = next
+	return nil
+}
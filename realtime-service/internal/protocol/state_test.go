@@ -0,0 +1,58 @@
+package protocol
+
+import "testing"
+
+func TestStateMachineValidCycle(t *testing.T) {
+	m := NewStateMachine()
+	if got := m.Current(); got != StateIdle {
+		t.Fatalf("new machine state = %s, want %s", got, StateIdle)
+	}
+
+	cycle := []State{StateListening, StateThinking, StateSpeaking, StateIdle}
+	for _, next := range cycle {
+		if err := m.Transition(next); err != nil {
+			t.Fatalf("Transition(%s): %v", next, err)
+		}
+		if got := m.Current(); got != next {
+			t.Fatalf("Current() = %s, want %s", got, next)
+		}
+	}
+}
+
+func TestStateMachineRejectsInvalidTransition(t *testing.T) {
+	m := NewStateMachine()
+	if err := m.Transition(StateSpeaking); err == nil {
+		t.Fatal("Transition(idle -> speaking) succeeded, want error")
+	}
+	if got := m.Current(); got != StateIdle {
+		t.Fatalf("Current() = %s after rejected transition, want %s", got, StateIdle)
+	}
+}
+
+func TestStateMachineListeningCanReturnToIdle(t *testing.T) {
+	m := NewStateMachine()
+	if err := m.Transition(StateListening); err != nil {
+		t.Fatalf("Transition(idle -> listening): %v", err)
+	}
+	if err := m.Transition(StateIdle); err != nil {
+		t.Fatalf("Transition(listening -> idle): %v", err)
+	}
+}
+
+func TestStateMachineConcurrentAccess(t *testing.T) {
+	m := NewStateMachine()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			m.Transition(StateListening)
+			m.Transition(StateIdle)
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		_ = m.Current()
+	}
+	<-done
+}
@@ -0,0 +1,274 @@
+// Package session implements the connection lifecycle for the realtime
+// WebSocket endpoint: registering raw sockets with a poller, dispatching
+// worker goroutines when data is ready, and cleaning up on disconnect.
+package session
+
+import (
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gobwas/ws"
+)
+
+// MessageHandler is notified that conn has data ready to read and is
+// responsible for reading and interpreting it (e.g. via a WebSocket
+// framing library). It is invoked on a pooled worker goroutine, never on
+// the poller goroutine, so it may block on the read without stalling the
+// poller itself; each blocked call does hold one of the hub's
+// defaultMaxWorkers slots, and once all are held no further connection
+// is dispatched. Returning an error unregisters conn.
+type MessageHandler func(conn net.Conn) error
+
+// SessionHub owns the poller and the set of registered connections. A
+// single background goroutine waits on the poller for readability events
+// and hands off the actual read/handle work to a bounded worker pool so
+// that idle connections cost no more than a file descriptor.
+type SessionHub struct {
+	Handler   MessageHandler
+	KeepAlive KeepAlive
+
+	poller  poller
+	workers chan struct{}
+
+	mu    sync.Mutex
+	conns map[int]*registration
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// registration is what the hub tracks per connection: the conn itself,
+// the means to stop its ping goroutine on Unregister, and the mutex that
+// serializes every writer of conn's socket (the pinger, Close's close
+// frame, and whatever the caller's Handler writes via Write) so they
+// never interleave bytes on the wire.
+type registration struct {
+	conn     net.Conn
+	stopPing chan struct{}
+	writeMu  sync.Mutex
+}
+
+// defaultMaxWorkers bounds the number of goroutines concurrently executing
+// Handler, regardless of how many connections are ready at once.
+const defaultMaxWorkers = 256
+
+// NewSessionHub creates a hub backed by the platform poller and starts its
+// event loop. Callers must call Close when the hub is no longer needed.
+// KeepAlive defaults to DefaultKeepAlive; set h.KeepAlive to the zero
+// value to disable ping/pong entirely.
+func NewSessionHub(handler MessageHandler) (*SessionHub, error) {
+	p, err := newPoller()
+	if err != nil {
+		return nil, err
+	}
+
+	h := &SessionHub{
+		Handler:   handler,
+		KeepAlive: DefaultKeepAlive(),
+		poller:    p,
+		workers:   make(chan struct{}, defaultMaxWorkers),
+		conns:     make(map[int]*registration),
+		closed:    make(chan struct{}),
+	}
+	go h.loop()
+	return h, nil
+}
+
+// Register adds conn to the poller so the hub is notified when it has data
+// ready to read, applies the initial read deadline, and starts the
+// keepalive ping goroutine. The caller retains ownership of conn.
+func (h *SessionHub) Register(conn net.Conn) error {
+	fd, err := fdOf(conn)
+	if err != nil {
+		return err
+	}
+
+	h.Touch(conn)
+
+	reg := &registration{conn: conn, stopPing: make(chan struct{})}
+
+	h.mu.Lock()
+	h.conns[fd] = reg
+	h.mu.Unlock()
+
+	if err := h.poller.add(fd); err != nil {
+		h.mu.Lock()
+		delete(h.conns, fd)
+		h.mu.Unlock()
+		return err
+	}
+
+	go h.startPinger(reg)
+	return nil
+}
+
+// Write serializes fn's access to conn's socket against the hub's own
+// ping and close-frame writes. Callers (typically a Handler bridging
+// frames to another system) must funnel every write to a registered conn
+// through Write rather than writing to conn directly, or concurrent
+// writers can tear frames on the wire. If conn isn't currently
+// registered, fn still runs, just without that protection.
+func (h *SessionHub) Write(conn net.Conn, fn func(net.Conn) error) error {
+	fd, err := fdOf(conn)
+	if err != nil {
+		return fn(conn)
+	}
+
+	h.mu.Lock()
+	reg, ok := h.conns[fd]
+	h.mu.Unlock()
+	if !ok {
+		return fn(conn)
+	}
+
+	reg.writeMu.Lock()
+	defer reg.writeMu.Unlock()
+	return fn(conn)
+}
+
+// Unregister removes conn from the poller and stops its keepalive
+// goroutine. It is safe to call more than once for the same connection.
+func (h *SessionHub) Unregister(conn net.Conn) {
+	fd, err := fdOf(conn)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	reg, ok := h.conns[fd]
+	delete(h.conns, fd)
+	h.mu.Unlock()
+
+	if ok {
+		close(reg.stopPing)
+	}
+	_ = h.poller.remove(fd)
+}
+
+// Close stops the event loop, sends every live connection a "going away"
+// close frame, and releases the poller. It does not wait for peers to
+// acknowledge the close frame.
+func (h *SessionHub) Close() error {
+	h.closeOnce.Do(func() {
+		close(h.closed)
+
+		h.mu.Lock()
+		regs := make([]*registration, 0, len(h.conns))
+		for _, reg := range h.conns {
+			regs = append(regs, reg)
+		}
+		h.mu.Unlock()
+
+		for _, reg := range regs {
+			// startPinger goroutines observe h.closed (already closed
+			// above) and exit on their own; no need to close stopPing
+			// here too, which would race with a concurrent Unregister.
+			reg.writeMu.Lock()
+			if h.KeepAlive.WriteTimeout > 0 {
+				reg.conn.SetWriteDeadline(time.Now().Add(h.KeepAlive.WriteTimeout))
+			}
+			_ = ws.WriteFrame(reg.conn, ws.NewCloseFrame(ws.NewCloseFrameBody(
+				ws.StatusGoingAway, "server shutting down",
+			)))
+			reg.writeMu.Unlock()
+		}
+	})
+	return h.poller.close()
+}
+
+// loop waits for readiness events and dispatches a bounded worker for each
+// ready connection. It never blocks on Handler itself.
+func (h *SessionHub) loop() {
+	for {
+		select {
+		case <-h.closed:
+			return
+		default:
+		}
+
+		ready, err := h.poller.wait()
+		if err != nil {
+			if err == errPollerClosed {
+				return
+			}
+			log.Println("session: poller wait failed:", err)
+			continue
+		}
+
+		for _, fd := range ready {
+			h.mu.Lock()
+			reg, ok := h.conns[fd]
+			h.mu.Unlock()
+			if !ok {
+				continue
+			}
+
+			h.workers <- struct{}{}
+			go func(fd int, conn net.Conn) {
+				defer func() { <-h.workers }()
+				h.dispatch(fd, conn)
+			}(fd, reg.conn)
+		}
+	}
+}
+
+// dispatch hands a ready connection to Handler. Every fd is registered
+// one-shot (see pollerImpl), so nothing else will dispatch conn again
+// until dispatch rearms it below — that's what keeps two worker
+// goroutines from ever reading the same connection concurrently. If
+// Handler reports an error (typically a closed connection or framing
+// error), the connection is unregistered instead of rearmed.
+func (h *SessionHub) dispatch(fd int, conn net.Conn) {
+	if h.Handler == nil {
+		return
+	}
+	if err := h.Handler(conn); err != nil {
+		log.Println("session: handler error:", err)
+		h.Unregister(conn)
+		return
+	}
+
+	h.mu.Lock()
+	_, stillRegistered := h.conns[fd]
+	h.mu.Unlock()
+	if !stillRegistered {
+		return
+	}
+
+	if err := h.poller.rearm(fd); err != nil {
+		log.Println("session: rearm failed:", err)
+		h.Unregister(conn)
+	}
+}
+
+// errUnsupportedConn is returned when a connection does not expose its
+// file descriptor, so it cannot be registered with the poller.
+var errUnsupportedConn = errors.New("session: connection does not support SyscallConn")
+
+// fdOf extracts the raw file descriptor backing conn so it can be
+// registered with the platform poller. *net.TCPConn, which is what
+// ws.UpgradeHTTP hands back, implements syscall.Conn.
+func fdOf(conn net.Conn) (int, error) {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return -1, errUnsupportedConn
+	}
+
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return -1, err
+	}
+
+	var fd int
+	err = raw.Control(func(fdPtr uintptr) {
+		fd = int(fdPtr)
+	})
+	if err != nil {
+		return -1, err
+	}
+	return fd, nil
+}
@@ -0,0 +1,71 @@
+package session
+
+import (
+	"net"
+	"time"
+
+	"github.com/gobwas/ws"
+)
+
+// KeepAlive configures the ping/pong liveness check SessionHub runs on
+// every registered connection. A half-open TCP connection (the peer
+// vanished without a FIN, e.g. a laptop going to sleep mid-lesson) is
+// otherwise invisible until the OS notices, which can take hours.
+type KeepAlive struct {
+	PingInterval time.Duration // how often the hub sends a ping
+	PongWait     time.Duration // read deadline applied after each ping/pong
+	WriteTimeout time.Duration // write deadline applied to ping/close frames
+}
+
+// DefaultKeepAlive pings often enough that most load balancers and NATs
+// (commonly defaulting to a 60s idle timeout) never see the connection
+// go quiet.
+func DefaultKeepAlive() KeepAlive {
+	return KeepAlive{
+		PingInterval: 30 * time.Second,
+		PongWait:     60 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+}
+
+// Touch resets conn's read deadline. Callers pass this as the pong
+// callback to protocol.ReadEventNotifyPong so a live pong keeps the connection
+// from being dropped as idle.
+func (h *SessionHub) Touch(conn net.Conn) {
+	if h.KeepAlive.PongWait == 0 {
+		return
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(h.KeepAlive.PongWait))
+}
+
+// startPinger sends a ping frame to reg's connection every PingInterval
+// until reg.stopPing is closed, the hub is closed, or a write fails (at
+// which point the connection is unregistered — a failed ping means the
+// peer is gone). The write is taken under reg.writeMu so it can't
+// interleave with whatever the caller's Handler is writing concurrently.
+func (h *SessionHub) startPinger(reg *registration) {
+	if h.KeepAlive.PingInterval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(h.KeepAlive.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-reg.stopPing:
+			return
+		case <-h.closed:
+			return
+		case <-ticker.C:
+			reg.writeMu.Lock()
+			_ = reg.conn.SetWriteDeadline(time.Now().Add(h.KeepAlive.WriteTimeout))
+			err := ws.WriteFrame(reg.conn, ws.NewPingFrame(nil))
+			reg.writeMu.Unlock()
+			if err != nil {
+				h.Unregister(reg.conn)
+				return
+			}
+		}
+	}
+}
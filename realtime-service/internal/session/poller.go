@@ -0,0 +1,44 @@
+package session
+
+import "errors"
+
+// errPollerClosed is returned by the fallback poller's wait once close
+// has been called, so the event loop can exit cleanly instead of logging
+// a spurious error. The epoll and kqueue backends return the OS error
+// from their wait call instead.
+var errPollerClosed = errors.New("session: poller closed")
+
+// poller is the minimal readiness-notification interface the hub needs.
+// It is implemented per-platform: epoll on Linux, kqueue on BSD/Darwin,
+// and a goroutine-per-connection fallback everywhere else (including
+// Windows, which has no cheap cross-process fd-based readiness API).
+type poller struct {
+	impl pollerImpl
+}
+
+// pollerImpl reports fd readiness one-shot: once add (or rearm) reports a
+// fd as ready via wait, that fd is not reported again until rearm is
+// called for it. This gives the hub a chance to fully drain a
+// connection on its worker goroutine before the poller can hand the same
+// fd to a second, concurrent worker.
+type pollerImpl interface {
+	add(fd int) error
+	remove(fd int) error
+	rearm(fd int) error
+	wait() (ready []int, err error)
+	close() error
+}
+
+func newPoller() (poller, error) {
+	impl, err := newPollerImpl()
+	if err != nil {
+		return poller{}, err
+	}
+	return poller{impl: impl}, nil
+}
+
+func (p poller) add(fd int) error     { return p.impl.add(fd) }
+func (p poller) remove(fd int) error  { return p.impl.remove(fd) }
+func (p poller) rearm(fd int) error   { return p.impl.rearm(fd) }
+func (p poller) wait() ([]int, error) { return p.impl.wait() }
+func (p poller) close() error         { return p.impl.close() }
@@ -0,0 +1,117 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package session
+
+import (
+	"sync"
+)
+
+// fallbackPoller is used on platforms without a cheap fd-readiness API
+// (notably Windows). Instead of a single poller goroutine, every
+// registered fd gets a dedicated goroutine that blocks until the fd
+// becomes readable per waitReadable, then reports itself through a
+// shared channel. This is exactly the per-connection-goroutine model the
+// epoll/kqueue backends exist to avoid, but it keeps the SessionHub API
+// and behavior identical across platforms.
+//
+// Like the epoll/kqueue backends, a fd is only ever watched for one
+// readiness notification at a time: watch reports a fd once and stops;
+// the caller must call rearm to start the next watch, giving it a chance
+// to fully drain the fd on a worker goroutine first.
+type fallbackPoller struct {
+	mu      sync.Mutex
+	cancel  map[int]chan struct{}
+	ready   chan int
+	closed  chan struct{}
+	closeWg sync.WaitGroup
+}
+
+func newPollerImpl() (pollerImpl, error) {
+	return &fallbackPoller{
+		cancel: make(map[int]chan struct{}),
+		ready:  make(chan int, 128),
+		closed: make(chan struct{}),
+	}, nil
+}
+
+func (p *fallbackPoller) add(fd int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.cancel[fd]; ok {
+		return nil
+	}
+	stop := make(chan struct{})
+	p.cancel[fd] = stop
+
+	p.closeWg.Add(1)
+	go p.watch(fd, stop)
+	return nil
+}
+
+// rearm starts watching fd again after a worker has finished draining it.
+// It is a no-op if fd was already removed (e.g. the connection closed
+// while its last read was still being handled).
+func (p *fallbackPoller) rearm(fd int) error {
+	p.mu.Lock()
+	stop, ok := p.cancel[fd]
+	p.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	p.closeWg.Add(1)
+	go p.watch(fd, stop)
+	return nil
+}
+
+func (p *fallbackPoller) remove(fd int) error {
+	p.mu.Lock()
+	stop, ok := p.cancel[fd]
+	delete(p.cancel, fd)
+	p.mu.Unlock()
+
+	if ok {
+		close(stop)
+	}
+	return nil
+}
+
+// watch blocks until fd is readable, stop is closed, or the poller is
+// closed, then reports the fd as ready exactly once. The caller must call
+// rearm to watch fd again.
+func (p *fallbackPoller) watch(fd int, stop chan struct{}) {
+	defer p.closeWg.Done()
+
+	if err := waitReadable(fd, stop); err != nil {
+		return
+	}
+
+	select {
+	case p.ready <- fd:
+	case <-stop:
+	case <-p.closed:
+	}
+}
+
+func (p *fallbackPoller) wait() ([]int, error) {
+	select {
+	case fd := <-p.ready:
+		return []int{fd}, nil
+	case <-p.closed:
+		return nil, errPollerClosed
+	}
+}
+
+func (p *fallbackPoller) close() error {
+	p.mu.Lock()
+	for _, stop := range p.cancel {
+		close(stop)
+	}
+	p.cancel = make(map[int]chan struct{})
+	p.mu.Unlock()
+
+	close(p.closed)
+	p.closeWg.Wait()
+	return nil
+}
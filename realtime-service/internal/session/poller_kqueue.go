@@ -0,0 +1,72 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package session
+
+import "golang.org/x/sys/unix"
+
+// kqueuePoller is the BSD/Darwin poller backend, used when epoll is
+// unavailable. It registers each fd as a one-shot read filter — EV_ONESHOT
+// fires at most once and then removes itself from the kqueue — and
+// reports ready fds from the kevent event list.
+type kqueuePoller struct {
+	kq     int
+	events []unix.Kevent_t
+}
+
+func newPollerImpl() (pollerImpl, error) {
+	kq, err := unix.Kqueue()
+	if err != nil {
+		return nil, err
+	}
+	return &kqueuePoller{
+		kq:     kq,
+		events: make([]unix.Kevent_t, 128),
+	}, nil
+}
+
+func (p *kqueuePoller) add(fd int) error {
+	changes := []unix.Kevent_t{{
+		Ident:  uint64(fd),
+		Filter: unix.EVFILT_READ,
+		Flags:  unix.EV_ADD | unix.EV_ENABLE | unix.EV_ONESHOT,
+	}}
+	_, err := unix.Kevent(p.kq, changes, nil, nil)
+	return err
+}
+
+// rearm re-registers fd for one more readiness notification: EV_ONESHOT
+// removes the filter from the kqueue as soon as it fires, so rearming is
+// just adding it back.
+func (p *kqueuePoller) rearm(fd int) error {
+	return p.add(fd)
+}
+
+func (p *kqueuePoller) remove(fd int) error {
+	changes := []unix.Kevent_t{{
+		Ident:  uint64(fd),
+		Filter: unix.EVFILT_READ,
+		Flags:  unix.EV_DELETE,
+	}}
+	_, err := unix.Kevent(p.kq, changes, nil, nil)
+	return err
+}
+
+func (p *kqueuePoller) wait() ([]int, error) {
+	n, err := unix.Kevent(p.kq, nil, p.events, nil)
+	if err != nil {
+		if err == unix.EINTR {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ready := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		ready = append(ready, int(p.events[i].Ident))
+	}
+	return ready, nil
+}
+
+func (p *kqueuePoller) close() error {
+	return unix.Close(p.kq)
+}
@@ -0,0 +1,72 @@
+//go:build linux
+
+package session
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// epollPoller is the Linux poller backend. A single epoll fd tracks every
+// registered connection; each fd is armed with EPOLLONESHOT so it fires
+// at most once until rearm is called, and wait blocks in unix.EpollWait
+// until at least one armed socket is readable.
+type epollPoller struct {
+	epfd   int
+	events []unix.EpollEvent
+}
+
+func newPollerImpl() (pollerImpl, error) {
+	epfd, err := unix.EpollCreate1(unix.EPOLL_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	return &epollPoller{
+		epfd:   epfd,
+		events: make([]unix.EpollEvent, 128),
+	}, nil
+}
+
+func (p *epollPoller) add(fd int) error {
+	ev := unix.EpollEvent{
+		Events: unix.EPOLLIN | unix.EPOLLONESHOT,
+		Fd:     int32(fd),
+	}
+	return unix.EpollCtl(p.epfd, unix.EPOLL_CTL_ADD, fd, &ev)
+}
+
+// rearm re-enables fd's one-shot registration after a worker has
+// finished draining it, so the next EpollWait can report it again.
+func (p *epollPoller) rearm(fd int) error {
+	ev := unix.EpollEvent{
+		Events: unix.EPOLLIN | unix.EPOLLONESHOT,
+		Fd:     int32(fd),
+	}
+	return unix.EpollCtl(p.epfd, unix.EPOLL_CTL_MOD, fd, &ev)
+}
+
+func (p *epollPoller) remove(fd int) error {
+	// The event argument is ignored for EPOLL_CTL_DEL on recent kernels
+	// but older kernels require a non-nil pointer.
+	ev := unix.EpollEvent{}
+	return unix.EpollCtl(p.epfd, unix.EPOLL_CTL_DEL, fd, &ev)
+}
+
+func (p *epollPoller) wait() ([]int, error) {
+	n, err := unix.EpollWait(p.epfd, p.events, -1)
+	if err != nil {
+		if err == unix.EINTR {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ready := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		ready = append(ready, int(p.events[i].Fd))
+	}
+	return ready, nil
+}
+
+func (p *epollPoller) close() error {
+	return unix.Close(p.epfd)
+}
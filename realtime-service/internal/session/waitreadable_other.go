@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package session
+
+import "time"
+
+// waitReadable is the last-resort fallback for platforms with neither a
+// poller backend nor a WSAPoll-style API. It cannot detect readability
+// directly, so it simply waits for stop to be closed or a short interval
+// to pass, relying on the caller's dispatch-and-retry loop to notice data
+// is available. This path exists only for exotic build targets and is
+// not expected to be exercised in production deployments.
+func waitReadable(fd int, stop chan struct{}) error {
+	select {
+	case <-stop:
+		return errPollerClosed
+	case <-time.After(50 * time.Millisecond):
+		return nil
+	}
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package session
+
+import "golang.org/x/sys/windows"
+
+// waitReadable blocks until fd has data available to read, stop is
+// closed, or an error occurs. It polls in short bursts via WSAPoll so a
+// closed stop channel is noticed promptly instead of blocking forever.
+func waitReadable(fd int, stop chan struct{}) error {
+	fds := []windows.WSAPollFd{{
+		Fd:     windows.Handle(fd),
+		Events: windows.POLLRDNORM,
+	}}
+
+	for {
+		select {
+		case <-stop:
+			return errPollerClosed
+		default:
+		}
+
+		n, err := windows.WSAPoll(fds, 250 /* ms */)
+		if err != nil {
+			return err
+		}
+		if n > 0 && fds[0].REvents&(windows.POLLRDNORM|windows.POLLHUP|windows.POLLERR) != 0 {
+			return nil
+		}
+	}
+}
@@ -1,50 +1,113 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
-	"github.com/gorilla/websocket"
+	"github.com/inworld-ai/runtime-language-learning-app/realtime-service/internal/auth"
+	"github.com/inworld-ai/runtime-language-learning-app/realtime-service/internal/backend"
+	"github.com/inworld-ai/runtime-language-learning-app/realtime-service/internal/handler"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
-}
+// Defaults applied when MAX_SESSIONS_PER_USER / MAX_UPGRADES_PER_MIN are
+// unset, generous enough for local development.
+const (
+	defaultMaxSessionsPerUser = 4
+	defaultMaxUpgradesPerMin  = 60
+)
 
-func echoServer(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Println("Upgrade failed:", err)
-		return
-	}
-	defer conn.Close()
+// shutdownTimeout bounds how long graceful shutdown waits for in-flight
+// requests (the HTTP upgrade itself, not the long-lived WebSocket
+// connections that follow it, which the handler's SessionHub handles
+// directly).
+const shutdownTimeout = 10 * time.Second
 
-	log.Println("Client connected!")
+// selectBackend picks a backend.Backend from SESSION_BACKEND, defaulting
+// to the echo placeholder so local development needs no Inworld runtime.
+func selectBackend() backend.Backend {
+	switch os.Getenv("SESSION_BACKEND") {
+	case "inworld":
+		return backend.InworldBackend{}
+	case "", "echo":
+		return backend.EchoBackend{}
+	default:
+		log.Printf("SESSION_BACKEND=%q not recognized, falling back to echo", os.Getenv("SESSION_BACKEND"))
+		return backend.EchoBackend{}
+	}
+}
 
-	for {
-		messageType, message, err := conn.ReadMessage()
-		if err != nil {
-			log.Println("Read failed:", err)
-			break
+// allowedOrigins parses ALLOWED_ORIGINS, a comma-separated list of exact
+// hosts or "*.example.com" wildcards.
+func allowedOrigins() auth.AllowedOrigins {
+	raw := os.Getenv("ALLOWED_ORIGINS")
+	if raw == "" {
+		return nil
+	}
+	var origins auth.AllowedOrigins
+	for _, o := range strings.Split(raw, ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			origins = append(origins, o)
 		}
+	}
+	return origins
+}
 
-		log.Printf("Received: %s", message)
-
-		err = conn.WriteMessage(messageType, message)
-		if err != nil {
-			log.Println("Write failed:", err)
-			break
-		}
+// envInt reads name as an int, falling back to def if it's unset or
+// unparseable.
+func envInt(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
 	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("%s=%q is not a valid integer, using default %d", name, v, def)
+		return def
+	}
+	return n
 }
 
 func main() {
-	http.HandleFunc("/session", echoServer)
-	log.Println("WebSocket Mock Server starting on :4000/ws")
-	err := http.ListenAndServe(":4000", nil)
+	h, err := handler.New(selectBackend())
 	if err != nil {
-		log.Fatal("ListenAndServe: ", err)
+		log.Fatal("handler.New: ", err)
+	}
+
+	h.Origins = allowedOrigins()
+	h.Tokens = auth.NewJWKSCache(os.Getenv("JWKS_URL"))
+	h.Limiter = auth.NewRateLimiter(
+		envInt("MAX_SESSIONS_PER_USER", defaultMaxSessionsPerUser),
+		envInt("MAX_UPGRADES_PER_MIN", defaultMaxUpgradesPerMin),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/session", h)
+	srv := &http.Server{Addr: ":4000", Handler: mux}
+
+	go func() {
+		log.Println("WebSocket Mock Server starting on :4000/session")
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("ListenAndServe: ", err)
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	<-sigCh
+
+	log.Println("Shutting down...")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Println("Shutdown:", err)
 	}
+	h.Close()
 }